@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package quantile
+
+import "math/rand"
+
+// Exemplar is a representative raw sample retained for a bin, together with
+// any opaque attributes (e.g. trace/span IDs) supplied at insertion time.
+type Exemplar struct {
+	Value      float64
+	Attributes any
+}
+
+// BinExemplars pairs a bin key with the exemplars retained for it.
+type BinExemplars[T uint16 | uint32] struct {
+	Key       T
+	Exemplars []Exemplar
+}
+
+// exemplarReservoir is a per-bin exemplar sampler modeled on OpenTelemetry's
+// AlignedHistogramBucketExemplarReservoir: one reservoir per bin key, each
+// insert reservoir-sampled independently. Slots are allocated lazily and
+// keyed by bin key rather than dense index, so memory stays O(active bins)
+// just like the sparse bin store.
+type exemplarReservoir[T uint16 | uint32] struct {
+	size  int
+	slots map[T]*reservoirSlot
+}
+
+type reservoirSlot struct {
+	seen      int64
+	exemplars []Exemplar
+}
+
+func newExemplarReservoir[T uint16 | uint32](size int) *exemplarReservoir[T] {
+	return &exemplarReservoir[T]{
+		size:  size,
+		slots: make(map[T]*reservoirSlot),
+	}
+}
+
+// insert reservoir-samples (v, attrs) into the slot for bin key k. It is
+// equivalent to insertWeighted with weight 1.
+func (r *exemplarReservoir[T]) insert(k T, v float64, attrs any) {
+	r.insertWeighted(k, v, attrs, 1)
+}
+
+// insertWeighted reservoir-samples (v, attrs) into the slot for bin key k,
+// treating it as standing in for weight observations instead of one. merge
+// uses this so that a retained exemplar coming from a slot that summarized
+// many observations is proportionally more likely to survive than one from
+// a slot that only saw a handful.
+func (r *exemplarReservoir[T]) insertWeighted(k T, v float64, attrs any, weight int64) {
+	if weight < 1 {
+		weight = 1
+	}
+
+	slot, ok := r.slots[k]
+	if !ok {
+		slot = &reservoirSlot{exemplars: make([]Exemplar, 0, r.size)}
+		r.slots[k] = slot
+	}
+
+	slot.seen += weight
+	if len(slot.exemplars) < r.size {
+		slot.exemplars = append(slot.exemplars, Exemplar{Value: v, Attributes: attrs})
+		return
+	}
+
+	if p := float64(r.size) * float64(weight) / float64(slot.seen); rand.Float64() < p {
+		slot.exemplars[rand.Intn(r.size)] = Exemplar{Value: v, Attributes: attrs}
+	}
+}
+
+// merge folds o's slots into r, slot by slot. Each retained exemplar in a
+// source slot stands in for roughly oslot.seen/len(oslot.exemplars) of the
+// observations that slot actually saw, so it's replayed through
+// insertWeighted with that weight rather than as a single observation —
+// otherwise a slot built from 10 samples and one built from 10M would
+// contribute equally to the merged reservoir.
+func (r *exemplarReservoir[T]) merge(o *exemplarReservoir[T]) {
+	for k, oslot := range o.slots {
+		if len(oslot.exemplars) == 0 {
+			continue
+		}
+
+		weight := oslot.seen / int64(len(oslot.exemplars))
+		if weight < 1 {
+			weight = 1
+		}
+		for _, e := range oslot.exemplars {
+			r.insertWeighted(k, e.Value, e.Attributes, weight)
+		}
+	}
+}
+
+// copy returns a deep copy of r.
+func (r *exemplarReservoir[T]) copy() *exemplarReservoir[T] {
+	dst := newExemplarReservoir[T](r.size)
+	for k, slot := range r.slots {
+		dstSlot := &reservoirSlot{seen: slot.seen, exemplars: make([]Exemplar, len(slot.exemplars))}
+		copy(dstSlot.exemplars, slot.exemplars)
+		dst.slots[k] = dstSlot
+	}
+	return dst
+}
+
+// EnableExemplars turns on per-bin exemplar tracking for s, retaining up to
+// size representative samples per bin. It is opt-in: sketches that never
+// call it pay no memory cost for exemplars. Calling it again resets any
+// exemplars already collected.
+func (s *Sketch[T]) EnableExemplars(size int) {
+	s.exemplars = newExemplarReservoir[T](size)
+}
+
+// Exemplars returns the exemplars retained for each bin that has at least
+// one, or nil if exemplar tracking was never enabled via EnableExemplars.
+// The returned slices are copies, safe for the caller to mutate.
+func (s *Sketch[T]) Exemplars() []BinExemplars[T] {
+	if s.exemplars == nil {
+		return nil
+	}
+
+	out := make([]BinExemplars[T], 0, len(s.exemplars.slots))
+	for k, slot := range s.exemplars.slots {
+		exemplars := make([]Exemplar, len(slot.exemplars))
+		copy(exemplars, slot.exemplars)
+		out = append(out, BinExemplars[T]{Key: k, Exemplars: exemplars})
+	}
+	return out
+}
@@ -0,0 +1,180 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package quantile
+
+import (
+	"math"
+	"sort"
+)
+
+// histBin is one (mean, count) bin of a StreamingHistogram.
+type histBin struct {
+	mean  float64
+	count int64
+}
+
+// StreamingHistogram implements the BigML/Ben-Haim streaming histogram: a
+// fixed maximum number of bins, each holding a (mean, count) pair, kept
+// sorted by mean and merged greedily whenever the bin count would exceed
+// maxBins. Unlike Sketch[T], which bounds relative error on a log-linear
+// bucket layout, StreamingHistogram gives unbiased absolute-value estimates
+// and can report arbitrary bucket boundaries, which suits small-cardinality
+// heavy-tailed signals or backends that want explicit bucket edges.
+type StreamingHistogram struct {
+	maxBins int
+	bins    []histBin
+}
+
+// NewStreamingHistogram returns an empty histogram that keeps at most
+// maxBins bins. maxBins is clamped to 1: compress needs at least one bin to
+// merge into and would otherwise index past a single-element slice as soon
+// as Insert grew it past zero.
+func NewStreamingHistogram(maxBins int) *StreamingHistogram {
+	if maxBins < 1 {
+		maxBins = 1
+	}
+	return &StreamingHistogram{maxBins: maxBins}
+}
+
+// Insert a single value into the histogram.
+func (h *StreamingHistogram) Insert(v float64) {
+	i := sort.Search(len(h.bins), func(i int) bool { return h.bins[i].mean >= v })
+	h.bins = append(h.bins, histBin{})
+	copy(h.bins[i+1:], h.bins[i:])
+	h.bins[i] = histBin{mean: v, count: 1}
+
+	h.compress()
+}
+
+// InsertMany values into the histogram.
+func (h *StreamingHistogram) InsertMany(values []float64) {
+	for _, v := range values {
+		h.Insert(v)
+	}
+}
+
+// compress merges adjacent bins, always picking the pair with the smallest
+// gap between means, until len(bins) <= maxBins.
+func (h *StreamingHistogram) compress() {
+	for len(h.bins) > h.maxBins {
+		minI := 0
+		minGap := math.Inf(1)
+		for i := 0; i < len(h.bins)-1; i++ {
+			gap := h.bins[i+1].mean - h.bins[i].mean
+			if gap < minGap {
+				minGap = gap
+				minI = i
+			}
+		}
+
+		a, b := h.bins[minI], h.bins[minI+1]
+		merged := histBin{
+			count: a.count + b.count,
+			mean:  (a.mean*float64(a.count) + b.mean*float64(b.count)) / float64(a.count+b.count),
+		}
+
+		h.bins[minI] = merged
+		h.bins = append(h.bins[:minI+1], h.bins[minI+2:]...)
+	}
+}
+
+// Merge o into h, without mutating o.
+func (h *StreamingHistogram) Merge(o *StreamingHistogram) {
+	h.bins = append(h.bins, o.bins...)
+	sort.Slice(h.bins, func(i, j int) bool { return h.bins[i].mean < h.bins[j].mean })
+	h.compress()
+}
+
+// Sum implements the Ben-Haim "sum" procedure: an estimate of the number of
+// observations <= b, found by linearly interpolating the count contributed
+// by the two bins straddling b.
+func (h *StreamingHistogram) Sum(b float64) float64 {
+	if len(h.bins) == 0 {
+		return 0
+	}
+	if b < h.bins[0].mean {
+		return 0
+	}
+	if b >= h.bins[len(h.bins)-1].mean {
+		return h.total()
+	}
+
+	i := sort.Search(len(h.bins), func(i int) bool { return h.bins[i].mean > b }) - 1
+
+	lo, hi := h.bins[i], h.bins[i+1]
+	weight := (b - lo.mean) / (hi.mean - lo.mean)
+	mb := float64(lo.count) + (float64(hi.count)-float64(lo.count))*weight
+
+	s := (float64(lo.count) + mb) / 2 * weight
+	for j := 0; j < i; j++ {
+		s += float64(h.bins[j].count)
+	}
+	s += float64(lo.count) / 2
+
+	return s
+}
+
+func (h *StreamingHistogram) total() float64 {
+	var total int64
+	for _, b := range h.bins {
+		total += b.count
+	}
+	return float64(total)
+}
+
+// Quantile returns an estimate of v such that q fraction of inserted values
+// are <= v, by binary-searching Sum for the target rank.
+func (h *StreamingHistogram) Quantile(q float64) float64 {
+	if len(h.bins) == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return h.bins[0].mean
+	}
+	if q >= 1 {
+		return h.bins[len(h.bins)-1].mean
+	}
+
+	target := q * h.total()
+	lo, hi := h.bins[0].mean, h.bins[len(h.bins)-1].mean
+	for i := 0; i < 64 && hi-lo > 1e-9*math.Max(1, math.Abs(hi)); i++ {
+		mid := lo + (hi-lo)/2
+		if h.Sum(mid) < target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+// Uniform returns B-1 split points that divide the histogram into B
+// roughly-equal-weight buckets, found by querying Quantile at B-1 evenly
+// spaced rank fractions.
+func (h *StreamingHistogram) Uniform(b int) []float64 {
+	if b < 2 {
+		return nil
+	}
+
+	splits := make([]float64, 0, b-1)
+	for i := 1; i < b; i++ {
+		splits = append(splits, h.Quantile(float64(i)/float64(b)))
+	}
+	return splits
+}
+
+// CopyTo makes a deep copy of this histogram into dst.
+func (h *StreamingHistogram) CopyTo(dst *StreamingHistogram) {
+	dst.maxBins = h.maxBins
+	dst.bins = append(dst.bins[:0], h.bins...)
+}
+
+// Copy returns a deep copy.
+func (h *StreamingHistogram) Copy() *StreamingHistogram {
+	dst := &StreamingHistogram{}
+	h.CopyTo(dst)
+	return dst
+}
@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package quantile
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestStreamingHistogramQuantile(t *testing.T) {
+	distributions := map[string]func(n int, seed int64) []float64{
+		"uniform":     uniformSamples,
+		"exponential": exponentialSamples,
+		"lognormal":   lognormalSamples,
+	}
+
+	for name, gen := range distributions {
+		values := gen(10000, 11)
+
+		sorted := append([]float64(nil), values...)
+		sort.Float64s(sorted)
+
+		h := NewStreamingHistogram(100)
+		h.InsertMany(values)
+
+		for _, q := range []float64{0.5, 0.9, 0.99} {
+			got := h.Quantile(q)
+			want := sorted[int(q*float64(len(sorted)-1))]
+
+			tolerance := 0.05 * math.Max(1, math.Abs(want))
+			if diff := math.Abs(got - want); diff > tolerance {
+				t.Errorf("%s: Quantile(%v) = %v, want ~%v (diff %v > tolerance %v)", name, q, got, want, diff, tolerance)
+			}
+		}
+	}
+}
+
+func TestStreamingHistogramSum(t *testing.T) {
+	values := uniformSamples(10000, 12)
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	h := NewStreamingHistogram(200)
+	h.InsertMany(values)
+
+	for _, frac := range []float64{0.25, 0.5, 0.75} {
+		b := sorted[int(frac*float64(len(sorted)-1))]
+
+		got := h.Sum(b)
+		want := float64(sort.SearchFloat64s(sorted, b))
+
+		tolerance := 0.05 * float64(len(sorted))
+		if diff := math.Abs(got - want); diff > tolerance {
+			t.Errorf("Sum(%v) = %v, want ~%v (diff %v > tolerance %v)", b, got, want, diff, tolerance)
+		}
+	}
+}
+
+func TestStreamingHistogramUniform(t *testing.T) {
+	values := uniformSamples(10000, 13)
+
+	h := NewStreamingHistogram(200)
+	h.InsertMany(values)
+
+	splits := h.Uniform(4)
+	if len(splits) != 3 {
+		t.Fatalf("got %d splits, want 3", len(splits))
+	}
+	for i := 1; i < len(splits); i++ {
+		if splits[i] <= splits[i-1] {
+			t.Errorf("splits not increasing: splits[%d]=%v <= splits[%d]=%v", i, splits[i], i-1, splits[i-1])
+		}
+	}
+
+	// Split i should land roughly (i+1)/4 of the way through the data.
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	for i, split := range splits {
+		wantFrac := float64(i+1) / 4
+		gotFrac := float64(sort.SearchFloat64s(sorted, split)) / float64(len(sorted))
+		if diff := math.Abs(gotFrac - wantFrac); diff > 0.05 {
+			t.Errorf("split %d at %v has rank fraction %v, want ~%v", i, split, gotFrac, wantFrac)
+		}
+	}
+}
+
+func TestStreamingHistogramMerge(t *testing.T) {
+	valuesA := uniformSamples(5000, 21)
+	valuesB := uniformSamples(5000, 22)
+
+	a := NewStreamingHistogram(100)
+	b := NewStreamingHistogram(100)
+	a.InsertMany(valuesA)
+	b.InsertMany(valuesB)
+	a.Merge(b)
+
+	all := append(append([]float64(nil), valuesA...), valuesB...)
+	sort.Float64s(all)
+
+	got := a.Quantile(0.5)
+	want := all[len(all)/2]
+	tolerance := 0.05 * math.Max(1, math.Abs(want))
+	if diff := math.Abs(got - want); diff > tolerance {
+		t.Errorf("merged median = %v, want ~%v (diff %v > tolerance %v)", got, want, diff, tolerance)
+	}
+}
+
+func TestNewStreamingHistogramClampsMaxBins(t *testing.T) {
+	h := NewStreamingHistogram(0)
+	h.InsertMany([]float64{1, 2, 3})
+	if len(h.bins) != 1 {
+		t.Errorf("got %d bins, want 1", len(h.bins))
+	}
+}
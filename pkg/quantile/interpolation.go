@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package quantile
+
+import "math"
+
+// InterpolationMode selects how Sketch.Quantile interpolates a value within
+// the bucket its target rank falls in.
+type InterpolationMode int
+
+const (
+	// InterpolationLinear interpolates linearly between the bucket's low
+	// and high boundaries, weighted by how far into the bucket the
+	// target rank falls. This is the default and matches the sketch's
+	// historical behavior.
+	InterpolationLinear InterpolationMode = iota
+
+	// InterpolationMidpoint always returns the midpoint of the bucket,
+	// ignoring where in the bucket the target rank falls.
+	InterpolationMidpoint
+
+	// InterpolationLog interpolates in log space, respecting the
+	// log-linear geometry of DDSketch buckets. With gamma close to 1
+	// its result stays very close to linear interpolation's.
+	InterpolationLog
+)
+
+// interpolate returns a value within [vLow, vHigh] (vLow <= vHigh, including
+// when both are negative) using m. weight is 1 at vLow and 0 at vHigh.
+func (m InterpolationMode) interpolate(vLow, vHigh, weight float64) float64 {
+	switch m {
+	case InterpolationMidpoint:
+		return (vLow + vHigh) / 2
+	case InterpolationLog:
+		return logInterpolate(vLow, vHigh, weight)
+	default:
+		return vLow*weight + vHigh*(1-weight)
+	}
+}
+
+// logInterpolate interpolates in log space. Bucket boundaries can be
+// negative (negative-value bins), so it interpolates the magnitude and
+// restores the sign, rather than taking the log of a negative number.
+func logInterpolate(vLow, vHigh, weight float64) float64 {
+	sign := 1.0
+	lo, hi := vLow, vHigh
+	if vLow < 0 {
+		sign = -1
+		lo, hi = -vLow, -vHigh
+	}
+	if lo <= 0 || hi <= 0 {
+		// Can't take a log across zero (e.g. the bucket straddles zero);
+		// fall back to linear interpolation.
+		return vLow*weight + vHigh*(1-weight)
+	}
+
+	return sign * math.Exp(math.Log(lo)+(1-weight)*(math.Log(hi)-math.Log(lo)))
+}
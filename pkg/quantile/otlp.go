@@ -0,0 +1,235 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package quantile
+
+import "math"
+
+// HistogramDataPoint is the shape OpenTelemetry's explicit-bucket histogram
+// aggregation expects: a count/sum/min/max summary plus per-bucket counts
+// for a caller-supplied set of boundaries.
+type HistogramDataPoint struct {
+	Count        uint64
+	Sum          float64
+	Min, Max     float64
+	BucketCounts []uint64
+}
+
+// ExponentialHistogramDataPoint is the shape OpenTelemetry's exponential
+// histogram aggregation expects: base-2 scale/offset bucketing, split into
+// positive and negative ranges around a zero bucket.
+type ExponentialHistogramDataPoint struct {
+	Count     uint64
+	Sum       float64
+	Min, Max  float64
+	Scale     int32
+	ZeroCount uint64
+
+	PositiveOffset       int32
+	PositiveBucketCounts []uint64
+
+	NegativeOffset       int32
+	NegativeBucketCounts []uint64
+}
+
+// ToExplicitBucketHistogram rebuckets s into len(boundaries)+1 explicit
+// buckets, the shape OpenTelemetry's AggregationExplicitBucketHistogram
+// consumes. boundaries must be sorted ascending. Each DDSketch bin's count
+// is distributed across the boundaries it straddles, proportional to the
+// fraction of the bin's log-width on each side, so a bin whose
+// [vLow, vHigh) spans a boundary splits its count between the two sides.
+// The split is in log space whatever the sketch's interpolation mode.
+func (s *Sketch[T]) ToExplicitBucketHistogram(c *Config, boundaries []float64) HistogramDataPoint {
+	h := HistogramDataPoint{
+		Count:        uint64(s.count),
+		Sum:          s.Basic.Sum,
+		Min:          s.Basic.Min,
+		Max:          s.Basic.Max,
+		BucketCounts: make([]uint64, len(boundaries)+1),
+	}
+	if s.count == 0 {
+		return h
+	}
+
+	for _, b := range s.bins {
+		vLow := c.f64(b.k)
+		var vHigh float64
+		if vLow >= 0 {
+			vHigh = vLow * c.gamma.v
+		} else {
+			vHigh = vLow / c.gamma.v
+		}
+
+		distributeBin(vLow, vHigh, float64(b.n), boundaries, h.BucketCounts)
+	}
+
+	return h
+}
+
+// distributeBin splits n observations spread across [vLow, vHigh) across
+// the buckets implied by boundaries, adding the result into counts. A bin
+// entirely within one bucket adds all of n to it; a bin straddling one or
+// more boundaries splits n proportional to the fraction of the bin's
+// log-width on each side, matching the log-linear bucket geometry that
+// InterpolationLog follows in Quantile. A bin that straddles zero
+// (lo < 0 < hi) has no consistent log scale, so it falls back to a linear
+// split, the same way logInterpolate does; ToExplicitBucketHistogram never
+// passes such a bin, since its vLow and vHigh always share a sign.
+func distributeBin(vLow, vHigh, n float64, boundaries []float64, counts []uint64) {
+	lo, hi := vLow, vHigh
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+
+	// bucketOf returns the explicit-bucket index v falls in.
+	bucketOf := func(v float64) int {
+		i := 0
+		for i < len(boundaries) && v >= boundaries[i] {
+			i++
+		}
+		return i
+	}
+
+	loBucket, hiBucket := bucketOf(lo), bucketOf(hi)
+	if loBucket == hiBucket || hi <= lo {
+		counts[loBucket] += uint64(math.Round(n))
+		return
+	}
+
+	// logWidth measures the position of v within [lo, hi) in log space,
+	// for use as a weight fraction. Below zero, the bucket's magnitudes
+	// shrink as v increases, so it's measured from hi down to v instead
+	// of from lo up to v.
+	logWidth := func(v float64) float64 {
+		switch {
+		case lo < 0 && hi > 0:
+			return v - lo // straddles zero: fall back to linear.
+		case hi <= 0:
+			return math.Log(-lo) - math.Log(-v)
+		default: // lo >= 0
+			return math.Log(v) - math.Log(lo)
+		}
+	}
+	totalLogWidth := logWidth(hi)
+
+	// Split n across the buckets the bin straddles, weighting each by
+	// the fraction of the bin's log-width that falls in it.
+	remaining := n
+	prev := lo
+	for bucket := loBucket; bucket <= hiBucket; bucket++ {
+		edge := hi
+		if bucket < len(boundaries) {
+			edge = math.Min(hi, boundaries[bucket])
+		}
+		frac := (logWidth(edge) - logWidth(prev)) / totalLogWidth
+		share := uint64(math.Round(n * frac))
+		if bucket == hiBucket {
+			share = uint64(math.Round(remaining))
+		}
+		counts[bucket] += share
+		remaining -= float64(share)
+		prev = edge
+	}
+}
+
+// ToExponentialHistogram maps s into OTel's exponential-histogram
+// scale/offset/bucket-count form at the requested scale.
+//
+// When 2^(2^-scale) matches the sketch's gamma, positive bins are mapped
+// losslessly: c.key(1) anchors the DDSketch key space to value 1 (base^0),
+// so key-c.key(1)-1 is exactly the OTel bucket index under the
+// (base^i, base^(i+1)] convention, with no rebucketing or precision loss.
+// That anchor only relies on c.key's documented behavior, not on any
+// internal field, so it holds regardless of how keys are encoded
+// underneath.
+//
+// Negative bins always go through the rebucketing path below: this
+// package's public API doesn't expose a value-independent way to turn a
+// negative bin's key back into its positive-side counterpart (T is
+// unsigned, and the negative/positive key relationship isn't part of the
+// Config contract), so there's no way to derive the lossless mapping for
+// them without depending on store.go's internal key encoding.
+func (s *Sketch[T]) ToExponentialHistogram(c *Config, scale int32) ExponentialHistogramDataPoint {
+	h := ExponentialHistogramDataPoint{
+		Count: uint64(s.count),
+		Sum:   s.Basic.Sum,
+		Min:   s.Basic.Min,
+		Max:   s.Basic.Max,
+		Scale: scale,
+	}
+	if s.count == 0 {
+		return h
+	}
+
+	base := math.Exp2(math.Exp2(-float64(scale)))
+	exact := math.Abs(base-c.gamma.v) < 1e-9
+
+	var exactOffset int32
+	if exact {
+		exactOffset = int32(c.key(1)) + 1
+	}
+
+	var posFirst, negFirst *int32
+	pos := map[int32]uint64{}
+	neg := map[int32]uint64{}
+
+	for _, b := range s.bins {
+		v := c.f64(b.k)
+		switch {
+		case v == 0:
+			h.ZeroCount += uint64(b.n)
+			continue
+		case v > 0:
+			var idx int32
+			if exact {
+				idx = int32(b.k) - exactOffset
+			} else {
+				idx = expIndex(v, base)
+			}
+			pos[idx] += uint64(b.n)
+			if posFirst == nil || idx < *posFirst {
+				posFirst = &idx
+			}
+		default:
+			idx := expIndex(-v, base)
+			neg[idx] += uint64(b.n)
+			if negFirst == nil || idx < *negFirst {
+				negFirst = &idx
+			}
+		}
+	}
+
+	if posFirst != nil {
+		h.PositiveOffset = *posFirst
+		h.PositiveBucketCounts = toBucketSlice(pos, *posFirst)
+	}
+	if negFirst != nil {
+		h.NegativeOffset = *negFirst
+		h.NegativeBucketCounts = toBucketSlice(neg, *negFirst)
+	}
+
+	return h
+}
+
+// expIndex returns the exponential-histogram bucket index for magnitude v,
+// following OTel's convention that bucket i covers (base^i, base^(i+1)].
+func expIndex(v, base float64) int32 {
+	return int32(math.Ceil(math.Log(v)/math.Log(base))) - 1
+}
+
+func toBucketSlice(m map[int32]uint64, first int32) []uint64 {
+	last := first
+	for idx := range m {
+		if idx > last {
+			last = idx
+		}
+	}
+
+	out := make([]uint64, last-first+1)
+	for idx, n := range m {
+		out[idx-first] = n
+	}
+	return out
+}
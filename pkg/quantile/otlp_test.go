@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package quantile
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestToExplicitBucketHistogram(t *testing.T) {
+	c := Default()
+	values := lognormalSamples(10000, 99)
+
+	s := &Sketch[uint16]{}
+	s.InsertMany(c, values)
+
+	boundaries := []float64{10, 20, 50, 100, 200, 500}
+	h := s.ToExplicitBucketHistogram(c, boundaries)
+
+	if len(h.BucketCounts) != len(boundaries)+1 {
+		t.Fatalf("got %d buckets, want %d", len(h.BucketCounts), len(boundaries)+1)
+	}
+
+	var total uint64
+	for _, n := range h.BucketCounts {
+		total += n
+	}
+	if total != h.Count {
+		t.Errorf("bucket counts sum to %d, want %d", total, h.Count)
+	}
+
+	// Compare against a true histogram over the same boundaries; DDSketch
+	// rebucketing should track it within a small tolerance.
+	want := make([]uint64, len(boundaries)+1)
+	for _, v := range values {
+		i := sort.SearchFloat64s(boundaries, v)
+		want[i]++
+	}
+
+	for i := range want {
+		diff := math.Abs(float64(h.BucketCounts[i]) - float64(want[i]))
+		tol := 0.05*float64(len(values)) + 5
+		if diff > tol {
+			t.Errorf("bucket %d: got %d, want ~%d (diff %v > tolerance %v)", i, h.BucketCounts[i], want[i], diff, tol)
+		}
+	}
+}
+
+func TestToExponentialHistogram(t *testing.T) {
+	c := Default()
+	values := lognormalSamples(10000, 100)
+
+	s := &Sketch[uint16]{}
+	s.InsertMany(c, values)
+
+	h := s.ToExponentialHistogram(c, 4)
+
+	var total uint64
+	total += h.ZeroCount
+	for _, n := range h.PositiveBucketCounts {
+		total += n
+	}
+	for _, n := range h.NegativeBucketCounts {
+		total += n
+	}
+	if total != h.Count {
+		t.Errorf("bucket counts (incl. zero) sum to %d, want %d", total, h.Count)
+	}
+	if len(h.NegativeBucketCounts) != 0 {
+		t.Errorf("lognormal samples are all positive, got %d negative buckets", len(h.NegativeBucketCounts))
+	}
+
+	base := math.Exp2(math.Exp2(-4))
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	// Spot-check that the bucket index of the minimum value, per OTel's
+	// (base^i, base^(i+1)] convention, lies within the reported range.
+	minIdx := expIndex(sorted[0], base)
+	if minIdx < h.PositiveOffset || int(minIdx-h.PositiveOffset) >= len(h.PositiveBucketCounts) {
+		t.Errorf("min value %v maps to bucket %d, outside reported range [%d, %d)",
+			sorted[0], minIdx, h.PositiveOffset, h.PositiveOffset+int32(len(h.PositiveBucketCounts)))
+	}
+}
+
+// TestToExponentialHistogramExactPath exercises the lossless key-offset
+// mapping for positive bins, which only activates when the requested
+// scale's base exactly matches the sketch's gamma. Default's gamma isn't
+// generally a power of two, so this picks the nearest integer scale and
+// skips if it doesn't land within the exactness tolerance — the same
+// tolerance ToExponentialHistogram itself uses to decide whether to take
+// the fast path.
+func TestToExponentialHistogramExactPath(t *testing.T) {
+	c := Default()
+
+	scale := int32(math.Round(-math.Log2(math.Log2(c.gamma.v))))
+	base := math.Exp2(math.Exp2(-float64(scale)))
+	if math.Abs(base-c.gamma.v) >= 1e-9 {
+		t.Skipf("gamma %v has no exact integer scale (nearest scale %d gives base %v); exact path not reachable", c.gamma.v, scale, base)
+	}
+
+	values := lognormalSamples(5000, 101)
+	s := &Sketch[uint16]{}
+	s.InsertMany(c, values)
+
+	exact := s.ToExponentialHistogram(c, scale)
+	rebucketed := s.ToExponentialHistogram(c, scale+1) // force the general path for comparison shape only
+
+	if len(exact.PositiveBucketCounts) == 0 {
+		t.Fatalf("exact path produced no positive buckets")
+	}
+	if len(rebucketed.PositiveBucketCounts) == 0 {
+		t.Fatalf("rebucketing path produced no positive buckets")
+	}
+
+	var total uint64
+	for _, n := range exact.PositiveBucketCounts {
+		total += n
+	}
+	total += exact.ZeroCount
+	if total != exact.Count {
+		t.Errorf("exact-path bucket counts sum to %d, want %d", total, exact.Count)
+	}
+}
@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package quantile
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// trueQuantile computes the exact q-quantile of sorted using the same rank
+// function Sketch.Quantile uses, so the comparison isolates interpolation
+// error from rank-selection differences.
+func trueQuantile(sorted []float64, q float64) float64 {
+	r := rank(len(sorted), q)
+	i := int(r)
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+func uniformSamples(n int, seed int64) []float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = rnd.Float64()*1000 + 1
+	}
+	return out
+}
+
+func exponentialSamples(n int, seed int64) []float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = rnd.ExpFloat64()*100 + 1
+	}
+	return out
+}
+
+func lognormalSamples(n int, seed int64) []float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.Exp(rnd.NormFloat64()*0.75 + 3)
+	}
+	return out
+}
+
+func mixedSignSamples(n int, seed int64) []float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	out := make([]float64, n)
+	for i := range out {
+		v := rnd.NormFloat64() * 500
+		if v == 0 {
+			v = 1
+		}
+		out[i] = v
+	}
+	return out
+}
+
+// modeTolerance is the maximum relative error (against the true value)
+// these tests pin for each interpolation mode, over the distributions
+// below. Log interpolation is tightest on log-linear bucket geometry;
+// midpoint is the loosest since it ignores where in the bucket the target
+// rank actually falls.
+var modeTolerance = map[InterpolationMode]float64{
+	InterpolationLinear:   0.03,
+	InterpolationMidpoint: 0.05,
+	InterpolationLog:      0.02,
+}
+
+func TestSketchQuantileInterpolationModes(t *testing.T) {
+	distributions := map[string]func(n int, seed int64) []float64{
+		"uniform":     uniformSamples,
+		"exponential": exponentialSamples,
+		"lognormal":   lognormalSamples,
+		"mixed-sign":  mixedSignSamples,
+	}
+	modes := []InterpolationMode{InterpolationLinear, InterpolationMidpoint, InterpolationLog}
+	quantiles := []float64{0.5, 0.9, 0.95, 0.99}
+
+	c := Default()
+
+	for name, gen := range distributions {
+		values := gen(10000, 42)
+
+		sorted := append([]float64(nil), values...)
+		sort.Float64s(sorted)
+
+		for _, mode := range modes {
+			// Log interpolation falls back to linear when a bucket
+			// straddles zero, so it offers no benefit on a sign-mixing
+			// distribution; don't pin a tighter bound than linear there.
+			tolerance := modeTolerance[mode]
+			if name == "mixed-sign" && mode == InterpolationLog {
+				tolerance = modeTolerance[InterpolationLinear]
+			}
+
+			s := &Sketch[uint16]{}
+			s.SetInterpolationMode(mode)
+			s.InsertMany(c, values)
+
+			for _, q := range quantiles {
+				got := s.Quantile(c, q)
+				want := trueQuantile(sorted, q)
+
+				relErr := math.Abs(got-want) / math.Max(1, math.Abs(want))
+				if relErr > tolerance {
+					t.Errorf("%s/%v: Quantile(%v) = %v, want ~%v (rel err %.4f > tolerance %.4f)",
+						name, mode, q, got, want, relErr, tolerance)
+				}
+			}
+		}
+	}
+}
+
+// TestSketchQuantileMinMax pins the documented special cases: q<=0 and q>=1
+// always return the exact tracked min/max, regardless of interpolation mode.
+func TestSketchQuantileMinMax(t *testing.T) {
+	c := Default()
+	values := uniformSamples(1000, 7)
+
+	for _, mode := range []InterpolationMode{InterpolationLinear, InterpolationMidpoint, InterpolationLog} {
+		s := &Sketch[uint16]{}
+		s.SetInterpolationMode(mode)
+		s.InsertMany(c, values)
+
+		if got := s.Quantile(c, 0); got != s.Basic.Min {
+			t.Errorf("%v: Quantile(0) = %v, want min %v", mode, got, s.Basic.Min)
+		}
+		if got := s.Quantile(c, 1); got != s.Basic.Max {
+			t.Errorf("%v: Quantile(1) = %v, want max %v", mode, got, s.Basic.Max)
+		}
+	}
+}
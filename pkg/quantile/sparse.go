@@ -22,6 +22,24 @@ type Sketch[T uint16 | uint32] struct {
 	sparseStore[T]
 
 	Basic summary.Summary `json:"summary"`
+
+	// exemplars is nil unless EnableExemplars has been called, so sketches
+	// that don't use it pay no memory cost.
+	exemplars *exemplarReservoir[T]
+
+	// interpolation is InterpolationLinear by default, matching the
+	// sketch's historical behavior.
+	interpolation InterpolationMode
+}
+
+// SetInterpolationMode selects how Quantile interpolates within a bucket.
+//
+// This is a per-Sketch setting rather than a Config field: Config is shared
+// across many sketches that key/merge against each other, while how one of
+// them reports a quantile is a per-sketch reporting concern, not part of
+// the bucketing scheme they need to agree on.
+func (s *Sketch[T]) SetInterpolationMode(m InterpolationMode) {
+	s.interpolation = m
 }
 
 func (s *Sketch[T]) Summary() *summary.Summary {
@@ -58,7 +76,38 @@ func (s *Sketch[T]) InsertMany(c *Config, values []float64) {
 
 	for _, v := range values {
 		s.Basic.Insert(v)
-		keys = append(keys, c.key(v))
+		k := c.key(v)
+		keys = append(keys, k)
+		if s.exemplars != nil {
+			s.exemplars.insert(k, v, nil)
+		}
+	}
+
+	s.InsertKeys(c, keys)
+	s.binPool.putKeyList(keys)
+}
+
+// InsertManyWithExemplars behaves like InsertMany, but additionally offers
+// attrs[i] (e.g. a trace or span ID) as the attributes for values[i] if it
+// is retained as an exemplar. Only meaningful once EnableExemplars has been
+// called; otherwise it behaves exactly like InsertMany.
+func (s *Sketch[T]) InsertManyWithExemplars(c *Config, values []float64, attrs []any) {
+	if s.binPool == nil {
+		s.initBinPool()
+	}
+	keys := s.binPool.getKeyList()
+
+	for i, v := range values {
+		s.Basic.Insert(v)
+		k := c.key(v)
+		keys = append(keys, k)
+		if s.exemplars != nil {
+			var a any
+			if i < len(attrs) {
+				a = attrs[i]
+			}
+			s.exemplars.insert(k, v, a)
+		}
 	}
 
 	s.InsertKeys(c, keys)
@@ -70,6 +119,9 @@ func (s *Sketch[T]) Reset() {
 	s.Basic.Reset()
 	s.count = 0
 	s.bins = s.bins[:0] // TODO: just release to a size tiered pool.
+	if s.exemplars != nil {
+		s.exemplars = newExemplarReservoir[T](s.exemplars.size)
+	}
 }
 
 // GetRawBins return raw bins information as string
@@ -88,6 +140,13 @@ func (s *Sketch[T]) Insert(c *Config, vals ...float64) {
 func (s *Sketch[T]) Merge(c *Config, o *Sketch[T]) {
 	s.Basic.Merge(o.Basic)
 	s.merge(c, &o.sparseStore)
+
+	if o.exemplars != nil {
+		if s.exemplars == nil {
+			s.exemplars = newExemplarReservoir[T](o.exemplars.size)
+		}
+		s.exemplars.merge(o.exemplars)
+	}
 }
 
 // Quantile returns v such that s.count*q items are <= v.
@@ -120,21 +179,29 @@ func (s *Sketch[T]) Quantile(c *Config, q float64) float64 {
 		weight := (n - rWant) / float64(b.n)
 
 		vLow := c.f64(b.k)
-		vHigh := vLow * c.gamma.v
+		var vHigh float64
+		if vLow >= 0 {
+			vHigh = vLow * c.gamma.v
+		} else {
+			// Negative bins are ordered the same as positive ones (by
+			// ascending value), but their magnitude shrinks as the key
+			// increases, so the upper boundary divides rather than
+			// multiplies.
+			vHigh = vLow / c.gamma.v
+		}
 
-		switch i {
-		case s.bins.Len():
-			vHigh = s.Basic.Max
-		case 0:
+		// Only clamp to the true global min/max when the target rank
+		// itself is rank 0 or rank count-1: a bucket can hold many
+		// points, and being the first or last bucket doesn't mean every
+		// rank in it is the extreme.
+		if i == 0 && rWant == 0 {
 			vLow = s.Basic.Min
 		}
+		if i == s.bins.Len()-1 && rWant >= float64(s.count-1) {
+			vHigh = s.Basic.Max
+		}
 
-		// TODO|PROD: Interpolate between bucket boundaries, correctly handling min, max,
-		// negative numbers.
-		// with a gamma of 1.02, interpolating to the center gives us a 1% abs
-		// error bound.
-		return (vLow*weight + vHigh*(1-weight))
-		// return vLow
+		return s.interpolation.interpolate(vLow, vHigh, weight)
 	}
 
 	// this can happen if count is greater than sum of bins
@@ -152,6 +219,13 @@ func (s *Sketch[T]) CopyTo(dst *Sketch[T]) {
 	copy(dst.bins, s.bins)
 	dst.count = s.count
 	dst.Basic = s.Basic
+	dst.interpolation = s.interpolation
+
+	if s.exemplars != nil {
+		dst.exemplars = s.exemplars.copy()
+	} else {
+		dst.exemplars = nil
+	}
 }
 
 // Copy returns a deep copy
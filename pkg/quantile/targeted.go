@@ -0,0 +1,216 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package quantile
+
+import (
+	"math"
+	"sort"
+)
+
+// Target is a single (quantile, epsilon) requirement: the rank error for
+// quantile Q must stay within Epsilon of the true rank.
+type Target struct {
+	Quantile float64
+	Epsilon  float64
+}
+
+// Targets is the set of quantiles a TargetedSketch is tuned for.
+type Targets []Target
+
+// f implements the targeted invariant from Cormode, Korn, Muthukrishnan and
+// Srivastava: the maximum allowed Δ for a tuple observed at rank r out of n
+// is the minimum, over all targets, of the per-target rank error bound.
+func (ts Targets) f(r, n float64) float64 {
+	if n == 0 {
+		return 0
+	}
+
+	min := math.Inf(1)
+	for _, t := range ts {
+		qn := t.Quantile * n
+		var v float64
+		switch {
+		case r >= qn:
+			v = 2 * t.Epsilon * r / t.Quantile
+		default:
+			v = 2 * t.Epsilon * (n - r) / (1 - t.Quantile)
+		}
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// tuple is one (v, g, Δ) entry as in the CKMS paper: v is the observed
+// value, g is the number of observations since the previous tuple (so the
+// rank of v is the running sum of g up to and including this tuple), and Δ
+// bounds the uncertainty in that rank.
+type tuple struct {
+	v     float64
+	g     int64
+	delta int64
+}
+
+// TargetedSketch tracks a small set of target quantiles with bounded rank
+// error, using the Cormode/Korn/Muthukrishnan/Srivastava (CKMS) biased
+// quantiles algorithm. Unlike Sketch[T], which bounds relative error across
+// the whole value range, TargetedSketch trades that generality for tighter
+// error at a handful of quantiles the caller cares about (e.g. p50/p95/p99).
+type TargetedSketch struct {
+	targets Targets
+	tuples  []tuple
+	n       int64
+
+	// inserts counts insertions since the last compress, so we can
+	// amortize compression instead of doing it on every insert.
+	inserts int64
+}
+
+// NewTargeted returns an empty TargetedSketch tuned for targets.
+func NewTargeted(targets ...Target) *TargetedSketch {
+	return &TargetedSketch{targets: Targets(targets)}
+}
+
+// compressEvery bounds how often Compress runs during Insert; running it on
+// every insert is correct but wasteful, since a single new tuple can only
+// violate the merge condition for its immediate neighbors.
+const compressEvery = 128
+
+// Insert a single value into the sketch.
+func (t *TargetedSketch) Insert(v float64) {
+	i := sort.Search(len(t.tuples), func(i int) bool { return t.tuples[i].v >= v })
+
+	var delta int64
+	switch {
+	case i == 0 || i == len(t.tuples):
+		// The new minimum or maximum is always known exactly.
+		delta = 0
+	default:
+		r := t.rankAt(i)
+		delta = int64(math.Floor(t.targets.f(r, float64(t.n+1)))) - 1
+		if delta < 0 {
+			delta = 0
+		}
+	}
+
+	newTuple := tuple{v: v, g: 1, delta: delta}
+	t.tuples = append(t.tuples, tuple{})
+	copy(t.tuples[i+1:], t.tuples[i:])
+	t.tuples[i] = newTuple
+
+	t.n++
+	t.inserts++
+	if t.inserts >= compressEvery {
+		t.Compress()
+		t.inserts = 0
+	}
+}
+
+// InsertMany values into the sketch.
+func (t *TargetedSketch) InsertMany(values []float64) {
+	for _, v := range values {
+		t.Insert(v)
+	}
+}
+
+// rankAt returns the rank of tuples[i], i.e. the sum of g for all tuples up
+// to and including i.
+func (t *TargetedSketch) rankAt(i int) float64 {
+	var r float64
+	for j := 0; j <= i; j++ {
+		r += float64(t.tuples[j].g)
+	}
+	return r
+}
+
+// Compress merges adjacent tuples that the targeted invariant says can be
+// combined without violating any target's error bound.
+func (t *TargetedSketch) Compress() {
+	if len(t.tuples) < 3 {
+		return
+	}
+
+	n := float64(t.n)
+	merged := make([]tuple, 0, len(t.tuples))
+	merged = append(merged, t.tuples[0])
+
+	r := float64(t.tuples[0].g)
+	for i := 1; i < len(t.tuples)-1; i++ {
+		cur := t.tuples[i]
+		r += float64(cur.g)
+
+		last := merged[len(merged)-1]
+		if float64(last.g+cur.g+cur.delta) <= t.targets.f(r, n) {
+			// Keep the successor's v/Δ (the bound we just validated was
+			// cur.delta, not last.delta) and fold the predecessor's g
+			// into it, rather than dropping cur and keeping last.
+			cur.g += last.g
+			merged[len(merged)-1] = cur
+			continue
+		}
+		merged = append(merged, cur)
+	}
+	merged = append(merged, t.tuples[len(t.tuples)-1])
+
+	t.tuples = merged
+}
+
+// Quantile returns an estimate of v such that q fraction of inserted values
+// are <= v, with rank error bounded by the epsilon configured for the
+// nearest target.
+func (t *TargetedSketch) Quantile(q float64) float64 {
+	if len(t.tuples) == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return t.tuples[0].v
+	}
+	if q >= 1 {
+		return t.tuples[len(t.tuples)-1].v
+	}
+
+	n := float64(t.n)
+	rWant := q * n
+	bound := t.targets.f(rWant, n) / 2
+
+	var r float64
+	prev := t.tuples[0].v
+	for _, tp := range t.tuples {
+		r += float64(tp.g)
+		if r+float64(tp.delta) > rWant+bound {
+			// tp is the tuple whose own (g, Δ) would violate the bound;
+			// canonical CKMS returns the predecessor, the last tuple
+			// that still satisfied it, not tp itself.
+			return prev
+		}
+		prev = tp.v
+	}
+	return t.tuples[len(t.tuples)-1].v
+}
+
+// Merge o into t, without mutating o.
+func (t *TargetedSketch) Merge(o *TargetedSketch) {
+	t.tuples = append(t.tuples, o.tuples...)
+	sort.Slice(t.tuples, func(i, j int) bool { return t.tuples[i].v < t.tuples[j].v })
+	t.n += o.n
+	t.Compress()
+}
+
+// CopyTo makes a deep copy of this sketch into dst.
+func (t *TargetedSketch) CopyTo(dst *TargetedSketch) {
+	dst.targets = t.targets
+	dst.n = t.n
+	dst.inserts = t.inserts
+	dst.tuples = append(dst.tuples[:0], t.tuples...)
+}
+
+// Copy returns a deep copy.
+func (t *TargetedSketch) Copy() *TargetedSketch {
+	dst := &TargetedSketch{}
+	t.CopyTo(dst)
+	return dst
+}
@@ -0,0 +1,84 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package quantile
+
+import (
+	"sort"
+	"testing"
+)
+
+// trueRankFraction returns the fraction of sorted values <= v, i.e. the
+// rank error metric the CKMS epsilons actually bound.
+func trueRankFraction(sorted []float64, v float64) float64 {
+	i := sort.SearchFloat64s(sorted, v)
+	for i < len(sorted) && sorted[i] <= v {
+		i++
+	}
+	return float64(i) / float64(len(sorted))
+}
+
+func TestTargetedSketchQuantileWithinEpsilon(t *testing.T) {
+	targets := Targets{
+		{Quantile: 0.5, Epsilon: 0.02},
+		{Quantile: 0.95, Epsilon: 0.005},
+		{Quantile: 0.99, Epsilon: 0.002},
+	}
+
+	distributions := map[string]func(n int, seed int64) []float64{
+		"uniform":     uniformSamples,
+		"exponential": exponentialSamples,
+		"lognormal":   lognormalSamples,
+	}
+
+	for name, gen := range distributions {
+		values := gen(20000, 7)
+
+		sorted := append([]float64(nil), values...)
+		sort.Float64s(sorted)
+
+		ts := NewTargeted(targets...)
+		ts.InsertMany(values)
+
+		for _, target := range targets {
+			got := ts.Quantile(target.Quantile)
+			rank := trueRankFraction(sorted, got)
+
+			// Allow a little slack over the configured epsilon: the
+			// bound is on rank error at the *insertion-time* n, and
+			// compression merges tuples eagerly (compressEvery), so a
+			// small amount of additional slop versus the strict
+			// theoretical bound is expected in practice.
+			tolerance := target.Epsilon + 0.01
+			if diff := rank - target.Quantile; diff > tolerance || diff < -tolerance {
+				t.Errorf("%s: quantile %v: got value %v at true rank %v (diff %v), want within %v of target",
+					name, target.Quantile, got, rank, diff, tolerance)
+			}
+		}
+	}
+}
+
+func TestTargetedSketchMerge(t *testing.T) {
+	targets := Targets{{Quantile: 0.5, Epsilon: 0.02}}
+
+	a := NewTargeted(targets...)
+	b := NewTargeted(targets...)
+
+	valuesA := uniformSamples(10000, 1)
+	valuesB := uniformSamples(10000, 2)
+	a.InsertMany(valuesA)
+	b.InsertMany(valuesB)
+
+	a.Merge(b)
+
+	all := append(append([]float64(nil), valuesA...), valuesB...)
+	sort.Float64s(all)
+
+	got := a.Quantile(0.5)
+	rank := trueRankFraction(all, got)
+	if diff := rank - 0.5; diff > 0.03 || diff < -0.03 {
+		t.Errorf("merged median: got value %v at true rank %v, want near 0.5", got, rank)
+	}
+}